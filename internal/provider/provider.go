@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/oxidecomputer/oxide.go/oxide"
+
+	oxideprovider "github.com/oxidecomputer/terraform-provider-oxide/oxide"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ provider.Provider = (*oxideProvider)(nil)
+
+// New returns a constructor for the plugin-framework provider, muxed
+// alongside the legacy SDKv2 provider in main.go.
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &oxideProvider{version: version}
+	}
+}
+
+// oxideProvider is the provider implementation.
+type oxideProvider struct {
+	version string
+}
+
+type oxideProviderModel struct {
+	Host  types.String `tfsdk:"host"`
+	Token types.String `tfsdk:"token"`
+}
+
+// Metadata returns the provider type name.
+func (p *oxideProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "oxide"
+	resp.Version = p.version
+}
+
+// Schema defines the schema for the provider.
+func (p *oxideProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"host": schema.StringAttribute{
+				Optional:    true,
+				Description: "URL of the root of the target server. Defaults to the OXIDE_HOST or OXIDE_TEST_HOST environment variable.",
+			},
+			"token": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Token used to authenticate to Oxide. Defaults to the OXIDE_TOKEN or OXIDE_TEST_TOKEN environment variable.",
+			},
+		},
+	}
+}
+
+// Configure prepares an Oxide API client for use by this provider's resources and data sources.
+func (p *oxideProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config oxideProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Resolving through oxideprovider.ResolveConfig, the same helper the
+	// SDKv2 provider calls from its ConfigureFunc, guarantees both halves
+	// of the muxed provider land on identical defaults.
+	host, token, err := oxideprovider.ResolveConfig(config.Host.ValueString(), config.Token.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid provider configuration", err.Error())
+		return
+	}
+
+	// ResolveConfig makes divergence between the two halves of the muxed
+	// provider impossible by construction, but check anyway in case that
+	// invariant is ever broken by a future change. A reported divergence
+	// isn't proof (see CheckConfigDivergence's doc comment on the
+	// concurrent-alias caveat), so this warns instead of failing
+	// configuration on what might be a false positive.
+	if diverged, detail := oxideprovider.CheckConfigDivergence(oxideprovider.ConfigHalfFramework, host, token); diverged {
+		resp.Diagnostics.AddWarning("Muxed provider configuration may have diverged", detail)
+	}
+
+	client, err := oxide.NewClient(token, oxideprovider.UserAgent(p.version), host)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create Oxide API client",
+			"API error: "+err.Error(),
+		)
+		return
+	}
+
+	resp.DataSourceData = client
+	resp.ResourceData = client
+
+	tflog.Info(ctx, "configured Oxide client", map[string]any{"host": host})
+}
+
+// Resources defines the resources implemented in the provider.
+func (p *oxideProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewImageResource,
+		NewImageLifecyclePolicyResource,
+	}
+}
+
+// DataSources defines the data sources implemented in the provider.
+func (p *oxideProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{}
+}
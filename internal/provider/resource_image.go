@@ -14,7 +14,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
@@ -40,20 +42,23 @@ type imageResource struct {
 }
 
 type imageResourceModel struct {
-	BlockSize        types.Int64    `tfsdk:"block_size"`
-	Description      types.String   `tfsdk:"description"`
-	Digest           types.Object   `tfsdk:"digest"`
-	ID               types.String   `tfsdk:"id"`
-	Name             types.String   `tfsdk:"name"`
-	OS               types.String   `tfsdk:"os"`
-	ProjectID        types.String   `tfsdk:"project_id"`
-	Size             types.Int64    `tfsdk:"size"`
-	SourceSnapshotID types.String   `tfsdk:"source_snapshot_id"`
-	SourceURL        types.String   `tfsdk:"source_url"`
-	TimeCreated      types.String   `tfsdk:"time_created"`
-	TimeModified     types.String   `tfsdk:"time_modified"`
-	Version          types.String   `tfsdk:"version"`
-	Timeouts         timeouts.Value `tfsdk:"timeouts"`
+	BlockSize         types.Int64    `tfsdk:"block_size"`
+	Description       types.String   `tfsdk:"description"`
+	Digest            types.Object   `tfsdk:"digest"`
+	ExpectedDigest    types.Object   `tfsdk:"expected_digest"`
+	ID                types.String   `tfsdk:"id"`
+	Name              types.String   `tfsdk:"name"`
+	OS                types.String   `tfsdk:"os"`
+	ProjectID         types.String   `tfsdk:"project_id"`
+	Size              types.Int64    `tfsdk:"size"`
+	SourceFile        types.String   `tfsdk:"source_file"`
+	SourceSnapshotID  types.String   `tfsdk:"source_snapshot_id"`
+	SourceURL         types.String   `tfsdk:"source_url"`
+	TimeCreated       types.String   `tfsdk:"time_created"`
+	TimeModified      types.String   `tfsdk:"time_modified"`
+	UploadConcurrency types.Int64    `tfsdk:"upload_concurrency"`
+	Version           types.String   `tfsdk:"version"`
+	Timeouts          timeouts.Value `tfsdk:"timeouts"`
 }
 
 type imageResourceDigestModel struct {
@@ -61,6 +66,11 @@ type imageResourceDigestModel struct {
 	Value types.String `tfsdk:"value"`
 }
 
+var imageResourceDigestAttributeTypes = map[string]attr.Type{
+	"type":  types.StringType,
+	"value": types.StringType,
+}
+
 // Metadata returns the resource type name.
 func (r *imageResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = "oxide_image"
@@ -132,6 +142,7 @@ func (r *imageResource) Schema(ctx context.Context, _ resource.SchemaRequest, re
 					stringvalidator.ExactlyOneOf(path.Expressions{
 						path.MatchRoot("source_url"),
 						path.MatchRoot("source_snapshot_id"),
+						path.MatchRoot("source_file"),
 					}...),
 					stringvalidator.ConflictsWith(path.Expressions{
 						path.MatchRoot("block_size"),
@@ -153,6 +164,47 @@ func (r *imageResource) Schema(ctx context.Context, _ resource.SchemaRequest, re
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"source_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path to a local file to upload as the image source, if applicable.",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.Expressions{
+						path.MatchRoot("block_size"),
+					}...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"expected_digest": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "Digest to verify the `source_file` upload against. If not set, the upload is only checked against the server-reported digest.",
+				Attributes: map[string]schema.Attribute{
+					"type": schema.StringAttribute{
+						Required:    true,
+						Description: "Digest type. Currently only `sha256` is supported.",
+						Validators: []validator.String{
+							stringvalidator.OneOf("sha256"),
+						},
+					},
+					"value": schema.StringAttribute{
+						Required:    true,
+						Description: "Expected digest value.",
+					},
+				},
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.RequiresReplace(),
+				},
+			},
+			"upload_concurrency": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(4),
+				Description: "Number of chunks to upload to the disk concurrently when `source_file` is set.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Create: true,
 				Read:   true,
@@ -222,6 +274,7 @@ func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	is := oxide.ImageSource{}
+	var uploadedDigest *oxide.Digest
 	if !plan.SourceSnapshotID.IsNull() {
 		is.Id = plan.SourceSnapshotID.ValueString()
 		is.Type = oxide.ImageSourceTypeSnapshot
@@ -233,10 +286,18 @@ func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest,
 			is.Type = oxide.ImageSourceTypeYouCanBootAnythingAsLongAsItsAlpine
 		}
 		is.BlockSize = oxide.BlockSize(plan.BlockSize.ValueInt64())
+	} else if !plan.SourceFile.IsNull() {
+		source, digest, diags := r.uploadSourceFile(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		is = source
+		uploadedDigest = &digest
 	} else {
 		resp.Diagnostics.AddError(
 			"Error creating image",
-			"One of `source_url` or `source_snapshot_id` must be set",
+			"One of `source_url`, `source_snapshot_id`, or `source_file` must be set",
 		)
 		return
 	}
@@ -253,7 +314,12 @@ func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	tflog.Trace(ctx, fmt.Sprintf("created image with ID: %v", image.Id), map[string]any{"success": true})
 
-	// Map response body to schema and populate Computed attribute values
+	// Map response body to schema and populate Computed attribute values. This
+	// happens before the digest check below so that an image the server has
+	// already created is always tracked in state, even if Create goes on to
+	// report an error: the API has no image delete, so an image this
+	// resource loses track of can never be cleaned up or recreated under the
+	// same name again.
 	plan.ID = types.StringValue(image.Id)
 	plan.Size = types.Int64Value(int64(image.Size))
 	plan.TimeCreated = types.StringValue(image.TimeCreated.String())
@@ -265,11 +331,7 @@ func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest,
 		Type:  types.StringValue(string(image.Digest.Type)),
 		Value: types.StringValue(image.Digest.Value),
 	}
-	attributeTypes := map[string]attr.Type{
-		"type":  types.StringType,
-		"value": types.StringType,
-	}
-	digest, diags := types.ObjectValueFrom(ctx, attributeTypes, dm)
+	digest, diags := types.ObjectValueFrom(ctx, imageResourceDigestAttributeTypes, dm)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -281,6 +343,20 @@ func (r *imageResource) Create(ctx context.Context, req resource.CreateRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	if uploadedDigest != nil && uploadedDigest.Value != image.Digest.Value {
+		resp.Diagnostics.AddError(
+			"Digest mismatch",
+			fmt.Sprintf(
+				"locally computed digest %q does not match server-reported digest %q for image %s. "+
+					"The image has already been created and is now tracked in state; the oxide API does not "+
+					"support image delete, so if the mismatch indicates corruption you must replace source_file "+
+					"and re-import under a new name, then remove this image out-of-band.",
+				uploadedDigest.Value, image.Digest.Value, image.Id,
+			),
+		)
+		return
+	}
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -343,11 +419,7 @@ func (r *imageResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		Type:  types.StringValue(string(image.Digest.Type)),
 		Value: types.StringValue(image.Digest.Value),
 	}
-	attributeTypes := map[string]attr.Type{
-		"type":  types.StringType,
-		"value": types.StringType,
-	}
-	digest, diags := types.ObjectValueFrom(ctx, attributeTypes, dm)
+	digest, diags := types.ObjectValueFrom(ctx, imageResourceDigestAttributeTypes, dm)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -0,0 +1,493 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/oxidecomputer/oxide.go/oxide"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource              = (*imageLifecyclePolicyResource)(nil)
+	_ resource.ResourceWithConfigure = (*imageLifecyclePolicyResource)(nil)
+)
+
+// NewImageLifecyclePolicyResource is a helper function to simplify the provider implementation.
+func NewImageLifecyclePolicyResource() resource.Resource {
+	return &imageLifecyclePolicyResource{}
+}
+
+// imageLifecyclePolicyResource is the resource implementation.
+type imageLifecyclePolicyResource struct {
+	client *oxide.Client
+}
+
+type imageLifecyclePolicyResourceModel struct {
+	ID                   types.String                        `tfsdk:"id"`
+	ProjectID            types.String                        `tfsdk:"project_id"`
+	SiloID               types.String                        `tfsdk:"silo_id"`
+	DryRun               types.Bool                          `tfsdk:"dry_run"`
+	Filter               *imageLifecyclePolicyFilterModel    `tfsdk:"filter"`
+	Selection            *imageLifecyclePolicySelectionModel `tfsdk:"selection"`
+	ScheduledForDeletion types.List                          `tfsdk:"scheduled_for_deletion"`
+	Retained             types.List                          `tfsdk:"retained"`
+	Timeouts             timeouts.Value                      `tfsdk:"timeouts"`
+}
+
+type imageLifecyclePolicyFilterModel struct {
+	NamePrefix            types.String `tfsdk:"name_prefix"`
+	OS                    types.String `tfsdk:"os"`
+	VersionRegex          types.String `tfsdk:"version_regex"`
+	MinimumAgeDays        types.Int64  `tfsdk:"minimum_age_days"`
+	MinimumReferenceCount types.Int64  `tfsdk:"minimum_reference_count"`
+	ExcludePinned         types.Bool   `tfsdk:"exclude_pinned"`
+}
+
+type imageLifecyclePolicySelectionModel struct {
+	Count   types.Int64  `tfsdk:"count"`
+	OrderBy types.String `tfsdk:"order_by"`
+}
+
+// Metadata returns the resource type name.
+func (r *imageLifecyclePolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "oxide_image_lifecycle_policy"
+}
+
+// Configure adds the provider configured client to the data source.
+func (r *imageLifecyclePolicyResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(*oxide.Client)
+}
+
+func (r *imageLifecyclePolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// Schema defines the schema for the resource.
+func (r *imageLifecyclePolicyResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Evaluates retention rules against images in a project or silo and reports which images " +
+			"would be kept or removed. Deletion is gated behind `dry_run` until the Oxide API supports image deletion.",
+		Attributes: map[string]schema.Attribute{
+			"project_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "ID of the project whose images this policy evaluates.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.Expressions{
+						path.MatchRoot("project_id"),
+						path.MatchRoot("silo_id"),
+					}...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"silo_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "ID of the silo whose images this policy evaluates. The value only sets the policy's `id`; " +
+					"images are listed without a project and the listing is not filtered by this ID.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"dry_run": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(true),
+				Description: "If true, candidates are only reported via `scheduled_for_deletion` and never deleted. " +
+					"Defaults to true until the Oxide API's image delete endpoint lands.",
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+			}),
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique, immutable, system-controlled identifier of the policy.",
+			},
+			"scheduled_for_deletion": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "IDs of images that match the retention rules and are candidates for deletion.",
+			},
+			"retained": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "IDs of images that match the filters but are retained by the selection rules or by `exclude_pinned`.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"filter": schema.SingleNestedBlock{
+				Description: "Narrows the set of images this policy considers.",
+				Attributes: map[string]schema.Attribute{
+					"name_prefix": schema.StringAttribute{
+						Optional:    true,
+						Description: "Only consider images whose name starts with this prefix.",
+					},
+					"os": schema.StringAttribute{
+						Optional:    true,
+						Description: "Only consider images for this OS distribution. Example: alpine",
+					},
+					"version_regex": schema.StringAttribute{
+						Optional:    true,
+						Description: "Only consider images whose version matches this regular expression.",
+					},
+					"minimum_age_days": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Only consider images at least this many days old.",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+					"minimum_reference_count": schema.Int64Attribute{
+						Optional: true,
+						Description: "Only consider images sourcing at least this many disks. Counted by " +
+							"listing disks in `project_id` and matching their source image; has no effect " +
+							"when scoping by `silo_id`, since disks aren't listable at silo scope.",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+					"exclude_pinned": schema.BoolAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     booldefault.StaticBool(true),
+						Description: "Exclude images tagged `pinned` from deletion regardless of other rules.",
+					},
+				},
+			},
+			"selection": schema.SingleNestedBlock{
+				Description: "Retention semantics applied within each `os`+`name` family after filtering.",
+				Attributes: map[string]schema.Attribute{
+					"count": schema.Int64Attribute{
+						Required:    true,
+						Description: "Number of most recent images to retain per `os`+`name` family.",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"order_by": schema.StringAttribute{
+						Optional: true,
+						Description: "Ordering used to determine which images are \"most recent\". " +
+							"Currently only `time_created_desc` is supported.",
+						Validators: []validator.String{
+							stringvalidator.OneOf("time_created_desc"),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Create evaluates the policy and sets the initial Terraform state.
+func (r *imageLifecyclePolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan imageLifecyclePolicyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	// The policy itself has no server-side representation; its identity is
+	// derived from the scope it evaluates so that re-applying the same
+	// project/silo and rules converges instead of replacing the resource.
+	if !plan.ProjectID.IsNull() {
+		plan.ID = types.StringValue(plan.ProjectID.ValueString())
+	} else {
+		plan.ID = types.StringValue(plan.SiloID.ValueString())
+	}
+
+	if diags := r.evaluate(ctx, &plan); diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	if diags := r.applyDeletions(ctx, &plan); diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	tflog.Trace(ctx, fmt.Sprintf("created image lifecycle policy for scope: %v", plan.ID.ValueString()), map[string]any{"success": true})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read refreshes the Terraform state with the latest evaluation of the policy.
+func (r *imageLifecyclePolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state imageLifecyclePolicyResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultTimeout())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if diags := r.evaluate(ctx, &state); diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update re-evaluates the policy and, when dry_run is false, deletes images
+// that moved into scheduled_for_deletion.
+func (r *imageLifecyclePolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan imageLifecyclePolicyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultTimeout())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	if diags := r.evaluate(ctx, &plan); diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	if diags := r.applyDeletions(ctx, &plan); diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete is a no-op: this resource only reports or actions on image
+// candidates, it has no server-side object of its own to remove.
+func (r *imageLifecyclePolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// evaluate lists the images in scope, applies the filter and selection
+// rules, and populates the computed attributes. It never deletes anything:
+// Read calls this on every plan/refresh, and deletion must only happen on
+// apply, so that side effect lives in applyDeletions, called from Create
+// and Update alone.
+func (r *imageLifecyclePolicyResource) evaluate(ctx context.Context, model *imageLifecyclePolicyResourceModel) (diags diag.Diagnostics) {
+	params := oxide.ImageListParams{
+		Limit:  1000,
+		SortBy: oxide.NameOrIdSortModeIdAscending,
+	}
+	if !model.ProjectID.IsNull() {
+		params.Project = oxide.NameOrId(model.ProjectID.ValueString())
+	}
+
+	images, err := r.client.ImageListAllPages(params)
+	if err != nil {
+		diags.AddError(
+			"Unable to list images",
+			"API error: "+err.Error(),
+		)
+		return diags
+	}
+
+	var versionRegex *regexp.Regexp
+	if model.Filter != nil && !model.Filter.VersionRegex.IsNull() {
+		re, err := regexp.Compile(model.Filter.VersionRegex.ValueString())
+		if err != nil {
+			diags.AddError(
+				"Invalid filter.version_regex",
+				"could not compile regular expression: "+err.Error(),
+			)
+			return diags
+		}
+		versionRegex = re
+	}
+
+	// referenceCounts maps image ID to the number of disks in project_id
+	// sourced from it. It stays nil (and the filter below is skipped) when
+	// minimum_reference_count isn't set, or when scoping by silo_id, since
+	// disks can only be listed within a project.
+	var referenceCounts map[string]int64
+	if model.Filter != nil && !model.Filter.MinimumReferenceCount.IsNull() {
+		if model.ProjectID.IsNull() {
+			diags.AddWarning(
+				"filter.minimum_reference_count ignored",
+				"minimum_reference_count counts disks sourced from an image, and disks can only be listed "+
+					"within a project; it has no effect when this policy scopes by silo_id.",
+			)
+		} else {
+			disks, err := r.client.DiskListAllPages(oxide.DiskListParams{
+				Project: oxide.NameOrId(model.ProjectID.ValueString()),
+				Limit:   1000,
+				SortBy:  oxide.NameOrIdSortModeIdAscending,
+			})
+			if err != nil {
+				diags.AddError(
+					"Unable to list disks",
+					"API error: "+err.Error(),
+				)
+				return diags
+			}
+			referenceCounts = make(map[string]int64, len(disks))
+			for _, disk := range disks {
+				if disk.ImageId == "" {
+					continue
+				}
+				referenceCounts[disk.ImageId]++
+			}
+		}
+	}
+
+	candidates := make([]oxide.Image, 0, len(images))
+	for _, image := range images {
+		if model.Filter != nil {
+			if !model.Filter.NamePrefix.IsNull() && !strings.HasPrefix(string(image.Name), model.Filter.NamePrefix.ValueString()) {
+				continue
+			}
+			if !model.Filter.OS.IsNull() && image.Os != model.Filter.OS.ValueString() {
+				continue
+			}
+			if versionRegex != nil && !versionRegex.MatchString(image.Version) {
+				continue
+			}
+			if !model.Filter.MinimumAgeDays.IsNull() {
+				minAge := time.Duration(model.Filter.MinimumAgeDays.ValueInt64()) * 24 * time.Hour
+				if time.Since(image.TimeCreated) < minAge {
+					continue
+				}
+			}
+			if referenceCounts != nil && referenceCounts[image.Id] < model.Filter.MinimumReferenceCount.ValueInt64() {
+				continue
+			}
+		}
+		candidates = append(candidates, image)
+	}
+
+	retentionCount := int64(1)
+	if model.Selection != nil && !model.Selection.Count.IsNull() {
+		retentionCount = model.Selection.Count.ValueInt64()
+	}
+
+	families := make(map[string][]oxide.Image)
+	for _, image := range candidates {
+		key := image.Os + "/" + string(image.Name)
+		families[key] = append(families[key], image)
+	}
+
+	var retained, scheduled []string
+	for _, family := range families {
+		sort.Slice(family, func(i, j int) bool {
+			return family[i].TimeCreated.After(family[j].TimeCreated)
+		})
+		for i, image := range family {
+			if int64(i) < retentionCount {
+				retained = append(retained, image.Id)
+				continue
+			}
+			if model.Filter != nil && model.Filter.ExcludePinned.ValueBool() && isPinned(image) {
+				retained = append(retained, image.Id)
+				continue
+			}
+			scheduled = append(scheduled, image.Id)
+		}
+	}
+
+	// families is a map, so the order candidates were appended to retained/
+	// scheduled above is randomized across runs. Sort each by image ID so
+	// Read produces the same list order every time for an unchanged set of
+	// images, rather than a spurious diff on every refresh.
+	sort.Strings(retained)
+	sort.Strings(scheduled)
+
+	scheduledList, d := types.ListValueFrom(ctx, types.StringType, scheduled)
+	diags.Append(d...)
+	retainedList, d := types.ListValueFrom(ctx, types.StringType, retained)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	model.ScheduledForDeletion = scheduledList
+	model.Retained = retainedList
+
+	return diags
+}
+
+// applyDeletions deletes every image in model.ScheduledForDeletion, unless
+// model.DryRun is true. Only Create and Update call this, never Read, so
+// that a plan-only refresh can never delete an image.
+func (r *imageLifecyclePolicyResource) applyDeletions(ctx context.Context, model *imageLifecyclePolicyResourceModel) (diags diag.Diagnostics) {
+	if model.DryRun.ValueBool() {
+		return diags
+	}
+
+	var scheduled []string
+	diags.Append(model.ScheduledForDeletion.ElementsAs(ctx, &scheduled, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for _, id := range scheduled {
+		if err := r.client.ImageDelete(oxide.ImageDeleteParams{Image: oxide.NameOrId(id)}); err != nil {
+			diags.AddError(
+				"Error deleting image",
+				fmt.Sprintf("API error deleting image %s: %s", id, err.Error()),
+			)
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// isPinned reports whether an image carries the `pinned` tag used to exclude
+// it from lifecycle policy deletion.
+func isPinned(image oxide.Image) bool {
+	for _, tag := range image.Tags {
+		if tag == "pinned" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/oxidecomputer/oxide.go/oxide"
+)
+
+// uploadProgressLogInterval bounds how often Create logs upload progress for
+// a source_file upload, regardless of how many chunks are in flight.
+const uploadProgressLogInterval = 5 * time.Second
+
+// uploadSourceFile streams plan.SourceFile to a disk in fixed-size chunks,
+// finalizes it to a snapshot, and returns an ImageSource pointing at that
+// snapshot along with the SHA-256 digest computed locally from the file.
+//
+// A failed apply is not resumable at the byte level: resource.CreateRequest
+// carries no private state for Create to reload, so a retry always
+// re-uploads the file from offset 0. What the retry does avoid is orphaning
+// a second import disk: it looks up the deterministically-named disk a
+// previous failed attempt may have left behind and reuses it instead of
+// calling DiskCreate again. Bulk-write import is idempotent per offset, so
+// re-sending already-written blocks costs bandwidth, not correctness.
+func (r *imageResource) uploadSourceFile(ctx context.Context, plan *imageResourceModel) (oxide.ImageSource, oxide.Digest, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	blockSize := plan.BlockSize.ValueInt64()
+	if blockSize <= 0 {
+		blockSize = 512 * 1024
+	}
+
+	concurrency := plan.UploadConcurrency.ValueInt64()
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	f, err := os.Open(plan.SourceFile.ValueString())
+	if err != nil {
+		diags.AddError("Error reading source_file", err.Error())
+		return oxide.ImageSource{}, oxide.Digest{}, diags
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		diags.AddError("Error reading source_file", err.Error())
+		return oxide.ImageSource{}, oxide.Digest{}, diags
+	}
+
+	diskName := oxide.Name(fmt.Sprintf("%s-import", plan.Name.ValueString()))
+
+	var diskID string
+	if existing, err := r.client.DiskView(oxide.DiskViewParams{
+		Project: oxide.NameOrId(plan.ProjectID.ValueString()),
+		Disk:    oxide.NameOrId(diskName),
+	}); err == nil {
+		diskID = existing.Id
+	} else {
+		disk, err := r.client.DiskCreate(oxide.DiskCreateParams{
+			Project: oxide.NameOrId(plan.ProjectID.ValueString()),
+			Body: &oxide.DiskCreate{
+				Name:        diskName,
+				Description: plan.Description.ValueString(),
+				Size:        oxide.ByteCount(info.Size()),
+				DiskSource: oxide.DiskSource{
+					Type:      oxide.DiskSourceTypeImportingBlocks,
+					BlockSize: oxide.BlockSize(blockSize),
+				},
+			},
+		})
+		if err != nil {
+			diags.AddError("Error creating import disk", "API error: "+err.Error())
+			return oxide.ImageSource{}, oxide.Digest{}, diags
+		}
+		diskID = disk.Id
+
+		if err := r.client.DiskBulkWriteImportStart(oxide.DiskBulkWriteImportStartParams{
+			Disk: oxide.NameOrId(diskID),
+		}); err != nil {
+			diags.AddError("Error starting bulk import", "API error: "+err.Error())
+			return oxide.ImageSource{}, oxide.Digest{}, diags
+		}
+	}
+
+	hasher := sha256.New()
+	if _, err := io.CopyN(hasher, io.NewSectionReader(f, 0, info.Size()), info.Size()); err != nil && err != io.EOF {
+		diags.AddError("Error hashing source_file", err.Error())
+		return oxide.ImageSource{}, oxide.Digest{}, diags
+	}
+	localDigest := hex.EncodeToString(hasher.Sum(nil))
+
+	if !plan.ExpectedDigest.IsNull() {
+		var expected imageResourceDigestModel
+		diags.Append(plan.ExpectedDigest.As(ctx, &expected, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return oxide.ImageSource{}, oxide.Digest{}, diags
+		}
+		if expected.Value.ValueString() != localDigest {
+			diags.AddError(
+				"Digest mismatch",
+				fmt.Sprintf("locally computed digest %q does not match expected_digest %q", localDigest, expected.Value.ValueString()),
+			)
+			return oxide.ImageSource{}, oxide.Digest{}, diags
+		}
+	}
+
+	if err := uploadChunks(ctx, r.client, diskID, f, info.Size(), blockSize, int(concurrency)); err != nil {
+		diags.AddError("Error uploading source_file", err.Error())
+		return oxide.ImageSource{}, oxide.Digest{}, diags
+	}
+
+	if err := r.client.DiskBulkWriteImportStop(oxide.DiskBulkWriteImportStopParams{
+		Disk: oxide.NameOrId(diskID),
+	}); err != nil {
+		diags.AddError("Error stopping bulk import", "API error: "+err.Error())
+		return oxide.ImageSource{}, oxide.Digest{}, diags
+	}
+
+	snapshotName := oxide.Name(fmt.Sprintf("%s-import-snapshot", plan.Name.ValueString()))
+	snapshot, err := r.client.DiskFinalizeImport(oxide.DiskFinalizeImportParams{
+		Disk: oxide.NameOrId(diskID),
+		Body: &oxide.FinalizeDisk{SnapshotName: snapshotName},
+	})
+	if err != nil {
+		diags.AddError("Error finalizing import", "API error: "+err.Error())
+		return oxide.ImageSource{}, oxide.Digest{}, diags
+	}
+
+	return oxide.ImageSource{
+			Type: oxide.ImageSourceTypeSnapshot,
+			Id:   snapshot.Id,
+		}, oxide.Digest{
+			Type:  oxide.DigestTypeSha256,
+			Value: localDigest,
+		}, diags
+}
+
+// uploadChunks writes f to disk in blockSize-aligned chunks, using up to
+// concurrency workers, and logs progress no more often than
+// uploadProgressLogInterval.
+func uploadChunks(ctx context.Context, client *oxide.Client, diskID string, f *os.File, size, blockSize int64, concurrency int) error {
+	type chunk struct {
+		offset int64
+		data   []byte
+	}
+
+	offsets := make(chan chunk)
+	errs := make(chan error, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	lastLog := time.Now()
+	var acknowledgedUpTo int64
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range offsets {
+				err := client.DiskBulkWriteImport(oxide.DiskBulkWriteImportParams{
+					Disk: oxide.NameOrId(diskID),
+					Body: &oxide.ImportBlocksBulkWrite{
+						Offset:            c.offset,
+						Base64EncodedData: base64.StdEncoding.EncodeToString(c.data),
+					},
+				})
+				if err != nil {
+					select {
+					case errs <- fmt.Errorf("offset %d: %w", c.offset, err):
+					default:
+					}
+					continue
+				}
+
+				mu.Lock()
+				if c.offset+int64(len(c.data)) > acknowledgedUpTo {
+					acknowledgedUpTo = c.offset + int64(len(c.data))
+				}
+				if time.Since(lastLog) >= uploadProgressLogInterval {
+					tflog.Info(ctx, "uploading source_file", map[string]any{
+						"acknowledged_up_to": acknowledgedUpTo,
+						"total_size":         size,
+					})
+					lastLog = time.Now()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var sendErr error
+offsetLoop:
+	for offset := int64(0); offset < size; offset += blockSize {
+		n := blockSize
+		if offset+n > size {
+			n = size - offset
+		}
+		buf := make([]byte, n)
+		if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+			sendErr = err
+			break offsetLoop
+		}
+
+		select {
+		case offsets <- chunk{offset: offset, data: buf}:
+		case err := <-errs:
+			sendErr = err
+			break offsetLoop
+		}
+	}
+	close(offsets)
+	wg.Wait()
+
+	if sendErr != nil {
+		return sendErr
+	}
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
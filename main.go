@@ -0,0 +1,66 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
+
+	"github.com/oxidecomputer/terraform-provider-oxide/internal/provider"
+	"github.com/oxidecomputer/terraform-provider-oxide/oxide"
+)
+
+// version is set via -ldflags at release build time.
+var version = "dev"
+
+// providerAddress is the registry address Terraform uses to look up this
+// provider. It must match the address configured by users of the provider.
+const providerAddress = "registry.terraform.io/oxidecomputer/oxide"
+
+func main() {
+	var debug bool
+	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
+	flag.Parse()
+
+	oxide.ProviderVersion = version
+
+	ctx := context.Background()
+
+	// The legacy SDKv2 provider speaks protocol version 5; upgrade it so it
+	// can be muxed together with the plugin-framework provider, which only
+	// speaks protocol version 6.
+	upgradedSDKProvider, err := tf5to6server.UpgradeServer(ctx, oxide.Provider().GRPCProvider)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	providers := []func() tfprotov6.ProviderServer{
+		providerserver.NewProtocol6(provider.New(version)()),
+		func() tfprotov6.ProviderServer {
+			return upgradedSDKProvider
+		},
+	}
+
+	muxServer, err := tf6muxserver.NewMuxServer(ctx, providers...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var serveOpts []tf6server.ServeOpt
+	if debug {
+		serveOpts = append(serveOpts, tf6server.WithManagedDebug())
+	}
+
+	if err := tf6server.Serve(providerAddress, muxServer.ProviderServer, serveOpts...); err != nil {
+		log.Fatal(err)
+	}
+}
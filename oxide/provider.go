@@ -6,13 +6,149 @@ package oxide
 
 import (
 	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	oxideSDK "github.com/oxidecomputer/oxide.go"
 )
 
-const defaultHost = "http://127.0.0.1:12220"
+const (
+	defaultHost      = "http://127.0.0.1:12220"
+	userAgentProduct = "terraform-provider-oxide"
+)
+
+// Names for the two halves of the muxed provider, passed to
+// CheckConfigDivergence.
+const (
+	ConfigHalfSDKv2     = "sdkv2"
+	ConfigHalfFramework = "framework"
+)
+
+// ProviderVersion is stamped by main.go from the build's version metadata
+// before Provider() is called, so the SDKv2 provider's User-Agent matches
+// the plugin-framework provider's.
+var ProviderVersion = "dev"
+
+// UserAgent returns the User-Agent string the Oxide API client should send.
+// Both the SDKv2 and plugin-framework providers call this so requests look
+// identical regardless of which one served them.
+func UserAgent(version string) string {
+	return fmt.Sprintf("%s/%s", userAgentProduct, version)
+}
+
+// ResolveConfig applies the shared host/token defaults and environment
+// variable fallbacks. It is the single source of truth for provider
+// configuration so the SDKv2 ConfigureFunc and the plugin-framework
+// provider's Configure method can't silently diverge.
+func ResolveConfig(host, token string) (string, string, error) {
+	if host == "" {
+		host = firstNonEmptyEnv([]string{"OXIDE_HOST", "OXIDE_TEST_HOST"}, defaultHost)
+	}
+	if token == "" {
+		token = firstNonEmptyEnv([]string{"OXIDE_TOKEN", "OXIDE_TEST_TOKEN"}, "")
+	}
+
+	if host == "" {
+		return "", "", fmt.Errorf("host must not be empty")
+	}
+	if token == "" {
+		return "", "", fmt.Errorf("token must not be empty")
+	}
+
+	return host, token, nil
+}
+
+// resolvedConfigTTL bounds how long a half's entry waits in resolvedConfigBy
+// for its sibling. Without it, a half that records an entry and then never
+// gets a matching call (e.g. its sibling's ResolveConfig fails before it
+// can call CheckConfigDivergence) would leak that entry into an unrelated
+// later configuration round.
+const resolvedConfigTTL = 10 * time.Second
+
+var (
+	resolvedConfigMu sync.Mutex
+	resolvedConfigBy = map[string]struct {
+		Host, Token string
+		RecordedAt  time.Time
+	}{}
+)
+
+// otherConfigHalf returns the name of the muxed provider half that isn't half.
+func otherConfigHalf(half string) string {
+	if half == ConfigHalfSDKv2 {
+		return ConfigHalfFramework
+	}
+	return ConfigHalfSDKv2
+}
+
+// CheckConfigDivergence records the (host, token) ResolveConfig produced for
+// one half of the muxed provider ("sdkv2" or "framework") and reports
+// whether it disagrees with what the other half recorded, clearing both
+// halves' entries afterward so a later call (e.g. for a different provider
+// alias) isn't compared against a stale entry from an unrelated
+// configuration round.
+//
+// The provider protocol gives us no identifier to correlate the two halves'
+// calls for a single ConfigureProvider RPC, so this relies on the mux
+// fanning each one out to every underlying provider back-to-back before the
+// next RPC arrives; under concurrent aliased providers that assumption can
+// be wrong, cross-wiring two unrelated rounds into a false positive.
+// Callers must therefore treat a reported divergence as a prompt to
+// investigate, not as proof, and must not fail configuration on it alone.
+//
+// Sharing ResolveConfig should make real divergence impossible; this only
+// catches the case where that invariant breaks, e.g. one half resolving
+// config itself instead of going through ResolveConfig.
+func CheckConfigDivergence(half, host, token string) (diverged bool, detail string) {
+	resolvedConfigMu.Lock()
+	defer resolvedConfigMu.Unlock()
+
+	otherHalf := otherConfigHalf(half)
+	other, ok := resolvedConfigBy[otherHalf]
+	if ok && time.Since(other.RecordedAt) > resolvedConfigTTL {
+		// The sibling's entry is too old to belong to this configuration
+		// round (its own CheckConfigDivergence call never arrived, e.g.
+		// because its ResolveConfig failed) — discard it rather than
+		// comparing against it.
+		delete(resolvedConfigBy, otherHalf)
+		ok = false
+	}
+	if !ok {
+		resolvedConfigBy[half] = struct {
+			Host, Token string
+			RecordedAt  time.Time
+		}{host, token, time.Now()}
+		return false, ""
+	}
+	delete(resolvedConfigBy, otherHalf)
+
+	if other.Host != host {
+		return true, fmt.Sprintf(
+			"the %s half resolved host %q but the %s half resolved host %q for the same provider configuration",
+			half, host, otherHalf, other.Host,
+		)
+	}
+	if other.Token != token {
+		return true, fmt.Sprintf(
+			"the %s half resolved a different token than the %s half for the same provider configuration",
+			half, otherHalf,
+		)
+	}
+	return false, ""
+}
+
+func firstNonEmptyEnv(keys []string, fallback string) string {
+	for _, key := range keys {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return fallback
+}
 
 func Provider() *schema.Provider {
 	return &schema.Provider{
@@ -49,15 +185,16 @@ func Provider() *schema.Provider {
 }
 
 func newProviderMeta(d *schema.ResourceData) (interface{}, error) {
-	host := d.Get("host").(string)
-	if host == "" {
-		return nil, fmt.Errorf("host must not be empty")
+	host, token, err := ResolveConfig(d.Get("host").(string), d.Get("token").(string))
+	if err != nil {
+		return nil, err
 	}
 
-	token := d.Get("token").(string)
-	if token == "" {
-		return nil, fmt.Errorf("token must not be empty")
+	// A reported divergence here isn't proof (see CheckConfigDivergence), so
+	// it's logged rather than failing configuration outright.
+	if diverged, detail := CheckConfigDivergence(ConfigHalfSDKv2, host, token); diverged {
+		log.Printf("[WARN] muxed provider configuration may have diverged: %s", detail)
 	}
 
-	return oxideSDK.NewClient(token, "terraform-provider-oxide", host)
+	return oxideSDK.NewClient(token, UserAgent(ProviderVersion), host)
 }